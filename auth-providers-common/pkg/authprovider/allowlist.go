@@ -0,0 +1,89 @@
+package authprovider
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/oauth2-proxy/oauth2-proxy/v7/pkg/sessions"
+)
+
+// AllowedOrgs maps an allowed org name to the set of teams within it that are allowed, e.g.
+// {"org1": {"team-a": true, "team-b": true}, "org2": {"*": true}}. A team of "*" means any
+// member of the org is allowed, regardless of team membership.
+type AllowedOrgs map[string]map[string]bool
+
+// ParseAllowedOrgs parses a value of the form "org1:team-a,org1:team-b,org2:*" into a set of
+// allowed (org, team) pairs.
+func ParseAllowedOrgs(spec string) (*AllowedOrgs, error) {
+	allowed := AllowedOrgs{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid allowed org entry %q: expected format org:team or org:*", entry)
+		}
+
+		org, team := parts[0], parts[1]
+		if allowed[org] == nil {
+			allowed[org] = map[string]bool{}
+		}
+		allowed[org][team] = true
+	}
+
+	if len(allowed) == 0 {
+		return nil, fmt.Errorf("no allowed orgs found in %q", spec)
+	}
+
+	return &allowed, nil
+}
+
+// Allows reports whether membership in any of the given orgs, combined with membership in any
+// of the given org:team-slug pairs, satisfies the allowlist.
+func (a *AllowedOrgs) Allows(orgs []string, teams []string) bool {
+	for _, org := range orgs {
+		if (*a)[org]["*"] {
+			return true
+		}
+	}
+	for _, team := range teams {
+		org, slug, ok := strings.Cut(team, ":")
+		if !ok {
+			continue
+		}
+		if (*a)[org][slug] || (*a)[org]["*"] {
+			return true
+		}
+	}
+	return false
+}
+
+// requireMembership wraps next with a check that the authenticated session's access token
+// belongs to a member of one of the allowed (org, team) pairs. Requests with no valid session
+// are passed through unchanged so oauth2-proxy can handle the login flow.
+func requireMembership(store sessions.SessionStore, memberships membershipFunc, allowed *AllowedOrgs, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess, err := store.Load(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		orgs, teams, err := memberships(sess.AccessToken)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to fetch memberships: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		if !allowed.Allows(orgs, teams) {
+			http.Error(w, "not a member of an allowed org or team", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,51 @@
+package authprovider
+
+import "testing"
+
+func TestParseAllowedOrgs(t *testing.T) {
+	allowed, err := ParseAllowedOrgs("org1:team-a,org1:team-b,org2:*")
+	if err != nil {
+		t.Fatalf("ParseAllowedOrgs returned error: %v", err)
+	}
+
+	if !(*allowed)["org1"]["team-a"] || !(*allowed)["org1"]["team-b"] {
+		t.Errorf("org1 teams not parsed correctly: %+v", *allowed)
+	}
+	if !(*allowed)["org2"]["*"] {
+		t.Errorf("org2 wildcard not parsed correctly: %+v", *allowed)
+	}
+}
+
+func TestParseAllowedOrgsInvalid(t *testing.T) {
+	for _, spec := range []string{"", "org1", "org1:", ":team-a", "   "} {
+		if _, err := ParseAllowedOrgs(spec); err == nil {
+			t.Errorf("ParseAllowedOrgs(%q) = nil error, want error", spec)
+		}
+	}
+}
+
+func TestAllowedOrgsAllows(t *testing.T) {
+	allowed, err := ParseAllowedOrgs("org1:team-a,org2:*")
+	if err != nil {
+		t.Fatalf("ParseAllowedOrgs returned error: %v", err)
+	}
+
+	cases := []struct {
+		name  string
+		orgs  []string
+		teams []string
+		want  bool
+	}{
+		{"org wildcard match", []string{"org2"}, nil, true},
+		{"team match", nil, []string{"org1:team-a"}, true},
+		{"team not in allowlist", nil, []string{"org1:team-b"}, false},
+		{"unrelated org/team", []string{"org3"}, []string{"org3:team-a"}, false},
+		{"no memberships", nil, nil, false},
+	}
+
+	for _, c := range cases {
+		if got := allowed.Allows(c.orgs, c.teams); got != c.want {
+			t.Errorf("%s: Allows(%v, %v) = %v, want %v", c.name, c.orgs, c.teams, got, c.want)
+		}
+	}
+}
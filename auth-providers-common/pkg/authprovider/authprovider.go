@@ -0,0 +1,174 @@
+// Package authprovider holds the scaffolding shared by every SCM-backed auth provider binary
+// (github-auth-provider, gitlab-auth-provider, gitea-auth-provider, ...): option loading,
+// cookie/session plumbing, the icon/state/id-token mux handlers, and org/team allowlisting.
+// Each binary implements Provider with the handful of pieces that are genuinely SCM-specific.
+package authprovider
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	oauth2proxy "github.com/oauth2-proxy/oauth2-proxy/v7"
+	"github.com/oauth2-proxy/oauth2-proxy/v7/pkg/apis/options"
+	"github.com/oauth2-proxy/oauth2-proxy/v7/pkg/sessions"
+	"github.com/oauth2-proxy/oauth2-proxy/v7/pkg/validation"
+	"github.com/obot-platform/tools/auth-providers-common/pkg/icon"
+	"github.com/obot-platform/tools/auth-providers-common/pkg/state"
+)
+
+// CommonOptions is the set of options every auth provider binary loads the same way. Provider
+// binaries embed it in their own Options struct so env.LoadEnvForStruct picks up these fields
+// alongside their SCM-specific ones.
+type CommonOptions struct {
+	ObotServerURL    string  `env:"OBOT_SERVER_URL"`
+	AuthCookieSecret string  `usage:"Secret used to encrypt cookie" env:"OBOT_AUTH_PROVIDER_COOKIE_SECRET"`
+	AuthEmailDomains string  `usage:"Email domains allowed for authentication" default:"*" env:"OBOT_AUTH_PROVIDER_EMAIL_DOMAINS"`
+	JWTSigningKey    *string `usage:"PEM-encoded RSA private key used to sign ID tokens for downstream services" optional:"true" env:"OBOT_AUTH_PROVIDER_JWT_SIGNING_KEY"`
+	JWTIssuer        *string `usage:"issuer claim to use when signing ID tokens" optional:"true" env:"OBOT_AUTH_PROVIDER_JWT_ISSUER"`
+	JWTAudience      *string `usage:"audience claim to use when signing ID tokens" optional:"true" env:"OBOT_AUTH_PROVIDER_JWT_AUDIENCE"`
+	GroupsCacheTTL   string  `usage:"how long to cache a session's org/team memberships before refetching them from the SCM API" default:"1h" env:"OBOT_AUTH_PROVIDER_GROUPS_CACHE_TTL"`
+}
+
+// Provider is implemented by each SCM-specific auth provider binary (github, gitlab, gitea, ...).
+type Provider interface {
+	// Name identifies the provider, e.g. "github".
+	Name() string
+	// ConfigureLegacy applies provider-specific settings - provider type, client id/secret,
+	// enterprise endpoints, native org/team restrictions - onto the legacy oauth2-proxy options.
+	ConfigureLegacy(legacyOpts *options.LegacyOptions) error
+	// FetchProfileIconURL returns the avatar URL for the given login.
+	FetchProfileIconURL(login string) (string, error)
+	// Memberships returns the orgs and team/group slugs (formatted "org:slug") that the holder
+	// of accessToken belongs to. Used for allowlist enforcement and ID token claims.
+	Memberships(accessToken string) (orgs []string, teams []string, err error)
+	// FetchDisplayName returns the holder of accessToken's display name, for the ID token's name
+	// claim. oauth2-proxy's session does not populate this for every provider, so providers fetch
+	// it themselves the same way they fetch org/team memberships.
+	FetchDisplayName(accessToken string) (string, error)
+}
+
+// Run wires up and serves the mux shared by every auth provider binary: the oauth2-proxy itself,
+// the /obot-get-state and /obot-get-icon-url handlers, optional org/team allowlisting, optional
+// ID token issuance, and group/org membership exposed via the "groups" state field and the
+// X-Forwarded-Groups header.
+func Run(provider Provider, common CommonOptions, allowed *AllowedOrgs) {
+	cookieSecret, err := base64.StdEncoding.DecodeString(common.AuthCookieSecret)
+	if err != nil {
+		fmt.Printf("failed to decode cookie secret: %v\n", err)
+		os.Exit(1)
+	}
+
+	if common.JWTSigningKey != nil && common.JWTIssuer == nil {
+		fmt.Println("OBOT_AUTH_PROVIDER_JWT_ISSUER is required when OBOT_AUTH_PROVIDER_JWT_SIGNING_KEY is set")
+		os.Exit(1)
+	}
+
+	legacyOpts := options.NewLegacyOptions()
+	if allowed != nil || common.JWTSigningKey != nil {
+		legacyOpts.LegacyHeaders.PassAccessToken = true
+	}
+
+	if err := provider.ConfigureLegacy(legacyOpts); err != nil {
+		fmt.Printf("failed to configure %s provider: %v\n", provider.Name(), err)
+		os.Exit(1)
+	}
+
+	oauthProxyOpts, err := legacyOpts.ToOptions()
+	if err != nil {
+		fmt.Printf("failed to convert legacy options to new options: %v\n", err)
+		os.Exit(1)
+	}
+
+	oauthProxyOpts.Server.BindAddress = ""
+	oauthProxyOpts.MetricsServer.BindAddress = ""
+	oauthProxyOpts.Cookie.Refresh = time.Hour
+	oauthProxyOpts.Cookie.Name = "obot_access_token"
+	oauthProxyOpts.Cookie.Secret = string(cookieSecret)
+	oauthProxyOpts.Cookie.Secure = strings.HasPrefix(common.ObotServerURL, "https://")
+	oauthProxyOpts.RawRedirectURL = common.ObotServerURL + "/oauth2/callback"
+	if common.AuthEmailDomains != "" {
+		oauthProxyOpts.EmailDomains = strings.Split(common.AuthEmailDomains, ",")
+	}
+
+	if err = validation.Validate(oauthProxyOpts); err != nil {
+		fmt.Printf("failed to validate options: %v\n", err)
+		os.Exit(1)
+	}
+
+	oauthProxy, err := oauth2proxy.NewOAuthProxy(oauthProxyOpts, oauth2proxy.NewValidator(oauthProxyOpts.EmailDomains, oauthProxyOpts.AuthenticatedEmailsFile))
+	if err != nil {
+		fmt.Printf("failed to create oauth2 proxy: %v\n", err)
+		os.Exit(1)
+	}
+
+	sessionStore, err := sessions.NewSessionStore(&oauthProxyOpts.Session, &oauthProxyOpts.Cookie)
+	if err != nil {
+		fmt.Printf("failed to create session store: %v\n", err)
+		os.Exit(1)
+	}
+
+	groupsCacheTTL, err := time.ParseDuration(common.GroupsCacheTTL)
+	if err != nil {
+		fmt.Printf("failed to parse groups cache TTL: %v\n", err)
+		os.Exit(1)
+	}
+	memberships := newGroupsCache(groupsCacheTTL).wrap(provider.Memberships)
+
+	var signer *idTokenSigner
+	if common.JWTSigningKey != nil {
+		signer, err = newIDTokenSigner(*common.JWTSigningKey, strOrEmpty(common.JWTIssuer), strOrEmpty(common.JWTAudience))
+		if err != nil {
+			fmt.Printf("failed to configure JWT signing: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "9999"
+	}
+
+	// guard applies the org/team allowlist, when configured, to every endpoint that exposes
+	// session state or mints credentials - not just root - so a session that merely authenticated
+	// successfully but isn't a member of an allowed org/team can't reach them directly.
+	guard := func(next http.Handler) http.Handler {
+		if allowed == nil {
+			return next
+		}
+		return requireMembership(sessionStore, memberships, allowed, next)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(fmt.Sprintf("http://127.0.0.1:%s", port)))
+	})
+	mux.Handle("/obot-get-state", guard(http.HandlerFunc(withGroupsInState(sessionStore, memberships, state.ObotGetState(oauthProxy)))))
+	mux.HandleFunc("/obot-get-icon-url", icon.ObotGetIconURL(provider.FetchProfileIconURL))
+
+	if signer != nil {
+		mux.Handle("/obot-get-id-token", guard(http.HandlerFunc(obotGetIDToken(memberships, provider.FetchDisplayName, sessionStore, signer))))
+		mux.HandleFunc("/.well-known/openid-configuration", obotOpenIDConfiguration(strOrEmpty(common.JWTIssuer)))
+		mux.HandleFunc("/keys", obotJWKS(signer))
+	}
+
+	mux.Handle("/", guard(withGroupsHeader(sessionStore, memberships, oauthProxy)))
+
+	fmt.Printf("listening on 127.0.0.1:%s\n", port)
+	if err := http.ListenAndServe("127.0.0.1:"+port, mux); !errors.Is(err, http.ErrServerClosed) {
+		fmt.Printf("failed to listen and serve: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// strOrEmpty dereferences s, or returns "" if s is nil.
+func strOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
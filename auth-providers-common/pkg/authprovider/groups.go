@@ -0,0 +1,149 @@
+package authprovider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oauth2-proxy/oauth2-proxy/v7/pkg/sessions"
+)
+
+// membershipFunc fetches the orgs and team/group slugs for an access token, as Provider.Memberships
+// does, but may be wrapped with caching.
+type membershipFunc func(accessToken string) (orgs []string, teams []string, err error)
+
+// displayNameFunc fetches the display name for an access token, as Provider.FetchDisplayName does.
+type displayNameFunc func(accessToken string) (string, error)
+
+// groupsCache memoizes Provider.Memberships lookups per access token for ttl, so that requests
+// on a hot path don't each call out to the upstream SCM API and risk hitting its rate limits.
+type groupsCache struct {
+	ttl   time.Duration
+	mu    sync.Mutex
+	byTok map[string]membershipEntry
+}
+
+type membershipEntry struct {
+	orgs, teams []string
+	expires     time.Time
+}
+
+func newGroupsCache(ttl time.Duration) *groupsCache {
+	c := &groupsCache{ttl: ttl, byTok: map[string]membershipEntry{}}
+	go c.sweepPeriodically()
+	return c
+}
+
+// sweepPeriodically evicts expired entries roughly once per ttl, so that byTok stays bounded by
+// the number of distinct tokens seen within a ttl window rather than growing for the life of the
+// process as tokens are rotated across logins and refreshes.
+func (c *groupsCache) sweepPeriodically() {
+	ticker := time.NewTicker(c.ttl)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+		c.mu.Lock()
+		for token, entry := range c.byTok {
+			if now.After(entry.expires) {
+				delete(c.byTok, token)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
+
+func (c *groupsCache) wrap(fetch membershipFunc) membershipFunc {
+	return func(accessToken string) ([]string, []string, error) {
+		c.mu.Lock()
+		if entry, ok := c.byTok[accessToken]; ok {
+			if time.Now().Before(entry.expires) {
+				c.mu.Unlock()
+				return entry.orgs, entry.teams, nil
+			}
+			delete(c.byTok, accessToken)
+		}
+		c.mu.Unlock()
+
+		orgs, teams, err := fetch(accessToken)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		c.mu.Lock()
+		c.byTok[accessToken] = membershipEntry{orgs: orgs, teams: teams, expires: time.Now().Add(c.ttl)}
+		c.mu.Unlock()
+
+		return orgs, teams, nil
+	}
+}
+
+// groups combines orgs and org:team memberships into the flat list surfaced as the "groups"
+// claim/header.
+func groups(orgs, teams []string) []string {
+	return append(append([]string{}, orgs...), teams...)
+}
+
+// withGroupsHeader sets X-Forwarded-Groups on the request, for the upstream to consume, based on
+// the session's memberships, before handing off to next. Requests with no valid session (e.g.
+// not yet authenticated) are passed through unchanged so oauth2-proxy can handle the login flow.
+func withGroupsHeader(store sessions.SessionStore, memberships membershipFunc, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Always strip any X-Forwarded-Groups the client sent on the original request, so a forged
+		// header can never reach next unsanitized - only a value we just derived from the session
+		// is allowed through.
+		r.Header.Del("X-Forwarded-Groups")
+		if sess, err := store.Load(r); err == nil {
+			if orgs, teams, err := memberships(sess.AccessToken); err == nil {
+				r.Header.Set("X-Forwarded-Groups", strings.Join(groups(orgs, teams), ","))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withGroupsInState wraps an /obot-get-state style JSON handler, adding a "groups" field to its
+// response body populated from the session's memberships.
+func withGroupsInState(store sessions.SessionStore, memberships membershipFunc, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sess, err := store.Load(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		orgs, teams, err := memberships(sess.AccessToken)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := httptest.NewRecorder()
+		next.ServeHTTP(rec, r)
+
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+
+		var body map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			w.WriteHeader(rec.Code)
+			w.Write(rec.Body.Bytes())
+			return
+		}
+		body["groups"] = groups(orgs, teams)
+
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			w.WriteHeader(rec.Code)
+			w.Write(rec.Body.Bytes())
+			return
+		}
+
+		w.WriteHeader(rec.Code)
+		w.Write(encoded)
+	}
+}
@@ -0,0 +1,129 @@
+package authprovider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/oauth2-proxy/oauth2-proxy/v7/pkg/sessions"
+)
+
+// fakeSessionStore is a minimal sessions.SessionStore that always loads the given session, or
+// fails to load if sess is nil.
+type fakeSessionStore struct {
+	sess *sessions.SessionState
+}
+
+func (f *fakeSessionStore) Save(http.ResponseWriter, *http.Request, *sessions.SessionState) error {
+	return nil
+}
+
+func (f *fakeSessionStore) Load(*http.Request) (*sessions.SessionState, error) {
+	if f.sess == nil {
+		return nil, http.ErrNoCookie
+	}
+	return f.sess, nil
+}
+
+func (f *fakeSessionStore) Clear(http.ResponseWriter, *http.Request) error { return nil }
+
+func (f *fakeSessionStore) VerifyConnection(context.Context) error { return nil }
+
+func TestGroupsCacheReturnsCachedWithinTTL(t *testing.T) {
+	calls := 0
+	fetch := func(string) ([]string, []string, error) {
+		calls++
+		return []string{"org1"}, nil, nil
+	}
+
+	wrapped := newGroupsCache(time.Hour).wrap(fetch)
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := wrapped("token"); err != nil {
+			t.Fatalf("wrapped() returned error: %v", err)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("fetch called %d times, want 1 (later calls should hit the cache)", calls)
+	}
+}
+
+func TestGroupsCacheRefetchesAfterExpiry(t *testing.T) {
+	calls := 0
+	fetch := func(string) ([]string, []string, error) {
+		calls++
+		return []string{"org1"}, nil, nil
+	}
+
+	wrapped := newGroupsCache(10 * time.Millisecond).wrap(fetch)
+
+	if _, _, err := wrapped("token"); err != nil {
+		t.Fatalf("wrapped() returned error: %v", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, _, err := wrapped("token"); err != nil {
+		t.Fatalf("wrapped() returned error: %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("fetch called %d times, want 2 (expired entry should be refetched)", calls)
+	}
+}
+
+func TestGroupsCacheSweepEvictsExpiredEntries(t *testing.T) {
+	ttl := 10 * time.Millisecond
+	c := newGroupsCache(ttl)
+
+	fetch := func(string) ([]string, []string, error) { return []string{"org1"}, nil, nil }
+	if _, _, err := c.wrap(fetch)("token"); err != nil {
+		t.Fatalf("wrap()(...) returned error: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * ttl)
+	for time.Now().Before(deadline) {
+		c.mu.Lock()
+		n := len(c.byTok)
+		c.mu.Unlock()
+		if n == 0 {
+			return
+		}
+		time.Sleep(ttl)
+	}
+
+	t.Fatal("expired entry was never swept from groupsCache.byTok")
+}
+
+func TestWithGroupsHeaderStripsClientSuppliedHeader(t *testing.T) {
+	memberships := func(string) ([]string, []string, error) { return []string{"org1"}, nil, nil }
+
+	next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+	t.Run("valid session overwrites the header", func(t *testing.T) {
+		store := &fakeSessionStore{sess: &sessions.SessionState{AccessToken: "token"}}
+		handler := withGroupsHeader(store, memberships, next)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Forwarded-Groups", "forged-admin-group")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		if got, want := req.Header.Get("X-Forwarded-Groups"), "org1"; got != want {
+			t.Errorf("X-Forwarded-Groups = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("no session still strips the forged header", func(t *testing.T) {
+		store := &fakeSessionStore{}
+		handler := withGroupsHeader(store, memberships, next)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Forwarded-Groups", "forged-admin-group")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		if got := req.Header.Get("X-Forwarded-Groups"); got != "" {
+			t.Errorf("X-Forwarded-Groups = %q, want empty", got)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package authprovider
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/oauth2-proxy/oauth2-proxy/v7/pkg/sessions"
+)
+
+const jwtKeyID = "obot-auth-provider"
+
+// idTokenSigner mints and serves RS256 ID tokens for downstream Obot services, along with the
+// OIDC discovery document and JWKS needed to verify them without a shared secret.
+type idTokenSigner struct {
+	privateKey *rsa.PrivateKey
+	issuer     string
+	audience   string
+}
+
+// newIDTokenSigner builds an idTokenSigner from a PEM-encoded RSA private key.
+func newIDTokenSigner(pemKey, issuer, audience string) (*idTokenSigner, error) {
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(pemKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWT signing key: %w", err)
+	}
+
+	return &idTokenSigner{privateKey: key, issuer: issuer, audience: audience}, nil
+}
+
+// idTokenClaims is the set of claims minted into the ID token for a logged-in user.
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Email  string   `json:"email"`
+	Name   string   `json:"name"`
+	Orgs   []string `json:"orgs"`
+	Teams  []string `json:"teams"`
+	Groups []string `json:"groups"`
+}
+
+func (s *idTokenSigner) mint(login, email, name string, orgs, teams, groups []string) (string, error) {
+	now := time.Now()
+	claims := idTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer,
+			Subject:   login,
+			Audience:  jwt.ClaimStrings{s.audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(10 * time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Email:  email,
+		Name:   name,
+		Orgs:   orgs,
+		Teams:  teams,
+		Groups: groups,
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = jwtKeyID
+
+	return token.SignedString(s.privateKey)
+}
+
+// jwks renders the signer's public key as a JSON Web Key Set.
+func (s *idTokenSigner) jwks() map[string]any {
+	pub := s.privateKey.PublicKey
+	return map[string]any{
+		"keys": []map[string]any{
+			{
+				"kty": "RSA",
+				"use": "sig",
+				"alg": "RS256",
+				"kid": jwtKeyID,
+				"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			},
+		},
+	}
+}
+
+// obotGetIDToken loads the oauth2-proxy session for the request, mints a short-lived ID token
+// for the authenticated user, and returns it both as JSON and as an Authorization header.
+func obotGetIDToken(memberships membershipFunc, displayName displayNameFunc, store sessions.SessionStore, signer *idTokenSigner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sess, err := store.Load(r)
+		if err != nil {
+			http.Error(w, "not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		orgs, teams, err := memberships(sess.AccessToken)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to fetch memberships: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		name, err := displayName(sess.AccessToken)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to fetch display name: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		idToken, err := signer.mint(sess.User, sess.Email, name, orgs, teams, groups(orgs, teams))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to mint ID token: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Authorization", "Bearer "+idToken)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": idToken})
+	}
+}
+
+func obotOpenIDConfiguration(issuer string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                                issuer,
+			"jwks_uri":                              issuer + "/keys",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+			"response_types_supported":              []string{"id_token"},
+			"subject_types_supported":               []string{"public"},
+		})
+	}
+}
+
+func obotJWKS(signer *idTokenSigner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(signer.jwks())
+	}
+}
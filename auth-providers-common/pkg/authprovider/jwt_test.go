@@ -0,0 +1,91 @@
+package authprovider
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func generateTestSigningKeyPEM(t *testing.T) string {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	return string(pem.EncodeToMemory(block))
+}
+
+func TestIDTokenSignerMintAndVerify(t *testing.T) {
+	signer, err := newIDTokenSigner(generateTestSigningKeyPEM(t), "https://issuer.example.com", "downstream")
+	if err != nil {
+		t.Fatalf("newIDTokenSigner returned error: %v", err)
+	}
+
+	tokenString, err := signer.mint("alice", "alice@example.com", "Alice Example", []string{"org1"}, []string{"org1:team-a"}, []string{"org1", "org1:team-a"})
+	if err != nil {
+		t.Fatalf("mint returned error: %v", err)
+	}
+
+	parsed, err := jwt.ParseWithClaims(tokenString, &idTokenClaims{}, func(token *jwt.Token) (any, error) {
+		if kid, _ := token.Header["kid"].(string); kid != jwtKeyID {
+			t.Errorf("kid header = %v, want %q", token.Header["kid"], jwtKeyID)
+		}
+		return &signer.privateKey.PublicKey, nil
+	})
+	if err != nil {
+		t.Fatalf("ParseWithClaims returned error: %v", err)
+	}
+	if !parsed.Valid {
+		t.Fatalf("token did not validate")
+	}
+
+	claims, ok := parsed.Claims.(*idTokenClaims)
+	if !ok {
+		t.Fatalf("claims were not decoded into idTokenClaims")
+	}
+
+	if claims.Issuer != "https://issuer.example.com" {
+		t.Errorf("issuer = %q, want %q", claims.Issuer, "https://issuer.example.com")
+	}
+	if claims.Subject != "alice" {
+		t.Errorf("subject = %q, want %q", claims.Subject, "alice")
+	}
+	if claims.Email != "alice@example.com" {
+		t.Errorf("email = %q, want %q", claims.Email, "alice@example.com")
+	}
+	if claims.Name != "Alice Example" {
+		t.Errorf("name = %q, want %q", claims.Name, "Alice Example")
+	}
+	if len(claims.Orgs) != 1 || claims.Orgs[0] != "org1" {
+		t.Errorf("orgs = %v, want [org1]", claims.Orgs)
+	}
+	if len(claims.Groups) != 2 {
+		t.Errorf("groups = %v, want 2 entries", claims.Groups)
+	}
+}
+
+func TestIDTokenSignerJWKS(t *testing.T) {
+	signer, err := newIDTokenSigner(generateTestSigningKeyPEM(t), "https://issuer.example.com", "downstream")
+	if err != nil {
+		t.Fatalf("newIDTokenSigner returned error: %v", err)
+	}
+
+	jwks := signer.jwks()
+	keys, ok := jwks["keys"].([]map[string]any)
+	if !ok || len(keys) != 1 {
+		t.Fatalf("jwks() = %#v, want a single-entry \"keys\" array", jwks)
+	}
+	if keys[0]["kid"] != jwtKeyID {
+		t.Errorf("kid = %v, want %q", keys[0]["kid"], jwtKeyID)
+	}
+	if keys[0]["kty"] != "RSA" {
+		t.Errorf("kty = %v, want RSA", keys[0]["kty"])
+	}
+}
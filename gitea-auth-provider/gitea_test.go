@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/oauth2-proxy/oauth2-proxy/v7/pkg/apis/options"
+)
+
+func TestConfigureLegacy(t *testing.T) {
+	g := &giteaProvider{opts: Options{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		GiteaURL:     "https://gitea.example.com/",
+	}}
+
+	legacyOpts := options.NewLegacyOptions()
+	if err := g.ConfigureLegacy(legacyOpts); err != nil {
+		t.Fatalf("ConfigureLegacy returned error: %v", err)
+	}
+
+	if got, want := legacyOpts.LegacyProvider.ProviderType, "oidc"; got != want {
+		t.Errorf("ProviderType = %q, want %q", got, want)
+	}
+	if got, want := legacyOpts.LegacyProvider.OIDCIssuerURL, "https://gitea.example.com"; got != want {
+		t.Errorf("OIDCIssuerURL = %q, want %q (trailing slash should be trimmed)", got, want)
+	}
+	if got, want := legacyOpts.LegacyProvider.ClientID, "client-id"; got != want {
+		t.Errorf("ClientID = %q, want %q", got, want)
+	}
+}
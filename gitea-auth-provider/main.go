@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/obot-platform/tools/auth-providers-common/pkg/authprovider"
+	"github.com/obot-platform/tools/auth-providers-common/pkg/env"
+)
+
+type Options struct {
+	authprovider.CommonOptions
+
+	ClientID         string  `env:"OBOT_GITEA_AUTH_PROVIDER_CLIENT_ID"`
+	ClientSecret     string  `env:"OBOT_GITEA_AUTH_PROVIDER_CLIENT_SECRET"`
+	GiteaURL         string  `usage:"base URL of the Gitea instance, e.g. https://gitea.example.com" env:"OBOT_GITEA_AUTH_PROVIDER_URL"`
+	GiteaAllowedOrgs *string `usage:"restrict logins to members of these org:team pairs, e.g. org1:team-a,org1:team-b,org2:* (comma-separated)" optional:"true" env:"OBOT_GITEA_AUTH_PROVIDER_ALLOWED_ORGS"`
+}
+
+func main() {
+	var opts Options
+	if err := env.LoadEnvForStruct(&opts); err != nil {
+		fmt.Printf("failed to load options: %v\n", err)
+		os.Exit(1)
+	}
+
+	var allowedOrgs *authprovider.AllowedOrgs
+	if opts.GiteaAllowedOrgs != nil {
+		var err error
+		allowedOrgs, err = authprovider.ParseAllowedOrgs(*opts.GiteaAllowedOrgs)
+		if err != nil {
+			fmt.Printf("failed to parse allowed orgs: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	authprovider.Run(&giteaProvider{opts: opts}, opts.CommonOptions, allowedOrgs)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/oauth2-proxy/oauth2-proxy/v7/pkg/apis/options"
+	"github.com/obot-platform/tools/github-auth-provider/pkg/profile"
+)
+
+// githubProvider implements authprovider.Provider for github.com and GitHub Enterprise Server.
+type githubProvider struct {
+	opts Options
+}
+
+// httpClient is used for this package's own GitHub API calls (org/team membership, /user).
+// It defaults to http.DefaultClient and is swapped for one trusting the enterprise root CA in
+// ConfigureLegacy, without touching the process-wide http.DefaultTransport.
+var httpClient = http.DefaultClient
+
+func (g *githubProvider) Name() string { return "github" }
+
+func (g *githubProvider) ConfigureLegacy(legacyOpts *options.LegacyOptions) error {
+	legacyOpts.LegacyProvider.ProviderType = "github"
+	legacyOpts.LegacyProvider.ProviderName = "github"
+	legacyOpts.LegacyProvider.ClientID = g.opts.ClientID
+	legacyOpts.LegacyProvider.ClientSecret = g.opts.ClientSecret
+
+	if g.opts.GitHubTeams != nil {
+		legacyOpts.LegacyProvider.GitHubTeam = *g.opts.GitHubTeams
+	}
+	if g.opts.GitHubOrg != nil {
+		legacyOpts.LegacyProvider.GitHubOrg = *g.opts.GitHubOrg
+	}
+	if g.opts.GitHubRepo != nil {
+		legacyOpts.LegacyProvider.GitHubRepo = *g.opts.GitHubRepo
+	}
+	if g.opts.GitHubToken != nil {
+		legacyOpts.LegacyProvider.GitHubToken = *g.opts.GitHubToken
+	}
+	if g.opts.GitHubAllowUsers != nil {
+		legacyOpts.LegacyProvider.GitHubUsers = strings.Split(*g.opts.GitHubAllowUsers, ",")
+	}
+
+	if g.opts.EnterpriseHostname != nil {
+		if strings.Contains(*g.opts.EnterpriseHostname, "/") {
+			return fmt.Errorf("invalid enterprise hostname %q: must not contain a path", *g.opts.EnterpriseHostname)
+		}
+
+		legacyOpts.LegacyProvider.LoginURL = fmt.Sprintf("https://%s/login/oauth/authorize", *g.opts.EnterpriseHostname)
+		legacyOpts.LegacyProvider.RedeemURL = fmt.Sprintf("https://%s/login/oauth/access_token", *g.opts.EnterpriseHostname)
+		legacyOpts.LegacyProvider.ValidateURL = fmt.Sprintf("https://%s/api/v3/", *g.opts.EnterpriseHostname)
+
+		profile.SetAPIBaseURL(fmt.Sprintf("https://%s/api/v3", *g.opts.EnterpriseHostname))
+	}
+
+	if g.opts.EnterpriseRootCA != nil {
+		caCert, err := os.ReadFile(*g.opts.EnterpriseRootCA)
+		if err != nil {
+			return fmt.Errorf("failed to read enterprise root CA: %w", err)
+		}
+
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return fmt.Errorf("failed to parse enterprise root CA %q", *g.opts.EnterpriseRootCA)
+		}
+
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{RootCAs: caCertPool}
+
+		httpClient = &http.Client{Transport: transport}
+		profile.SetHTTPClient(httpClient)
+	}
+
+	return nil
+}
+
+func (g *githubProvider) FetchProfileIconURL(login string) (string, error) {
+	return profile.FetchGitHubProfileIconURL(login)
+}
+
+func (g *githubProvider) Memberships(accessToken string) ([]string, []string, error) {
+	orgs, err := fetchGitHubOrgs(accessToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	teams, err := fetchGitHubTeams(accessToken, g.teamNameField())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return orgs, teams, nil
+}
+
+// FetchDisplayName returns the GitHub display name (falling back to the login) for the holder of
+// accessToken. oauth2-proxy's GitHub provider only populates the session's User and Email, so this
+// fetches /user directly rather than relying on a session field it never fills in.
+func (g *githubProvider) FetchDisplayName(accessToken string) (string, error) {
+	var user struct {
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := getGitHubJSON(accessToken, "/user", &user); err != nil {
+		return "", err
+	}
+	if user.Name != "" {
+		return user.Name, nil
+	}
+	return user.Login, nil
+}
+
+// teamNameField reports whether team memberships should be formatted using the team's slug or
+// its display name, per OBOT_GITHUB_AUTH_PROVIDER_TEAM_NAME_FIELD. Defaults to "slug".
+func (g *githubProvider) teamNameField() string {
+	if g.opts.TeamNameField == nil {
+		return "slug"
+	}
+	return *g.opts.TeamNameField
+}
+
+func fetchGitHubOrgs(token string) ([]string, error) {
+	var orgs []struct {
+		Login string `json:"login"`
+	}
+	if err := getGitHubJSON(token, "/user/orgs", &orgs); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(orgs))
+	for _, org := range orgs {
+		names = append(names, org.Login)
+	}
+	return names, nil
+}
+
+func fetchGitHubTeams(token, nameField string) ([]string, error) {
+	var teams []struct {
+		Slug         string `json:"slug"`
+		Name         string `json:"name"`
+		Organization struct {
+			Login string `json:"login"`
+		} `json:"organization"`
+	}
+	if err := getGitHubJSON(token, "/user/teams", &teams); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(teams))
+	for _, team := range teams {
+		name := team.Slug
+		if nameField == "name" {
+			name = team.Name
+		}
+		names = append(names, team.Organization.Login+":"+name)
+	}
+	return names, nil
+}
+
+func getGitHubJSON(token, path string, out any) error {
+	req, err := http.NewRequest(http.MethodGet, profile.APIBaseURL()+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, path)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,67 @@
+// Package profile fetches GitHub user profile information needed by the auth provider.
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// apiBaseURL is the base URL used to reach the GitHub REST API. It defaults to the public
+// api.github.com and can be pointed at a GitHub Enterprise Server instance via SetAPIBaseURL.
+var apiBaseURL = "https://api.github.com"
+
+// httpClient is used for all GitHub API calls made by this package. It defaults to
+// http.DefaultClient and can be overridden via SetHTTPClient, e.g. to trust a GitHub Enterprise
+// Server instance's root CA, without touching http.DefaultClient/http.DefaultTransport.
+var httpClient = http.DefaultClient
+
+// SetAPIBaseURL overrides the GitHub API base URL, e.g. to talk to a GitHub Enterprise Server
+// instance at https://<host>/api/v3 instead of the public api.github.com.
+func SetAPIBaseURL(url string) {
+	if url == "" {
+		return
+	}
+	apiBaseURL = strings.TrimSuffix(url, "/")
+}
+
+// APIBaseURL returns the GitHub API base URL currently in effect.
+func APIBaseURL() string {
+	return apiBaseURL
+}
+
+// SetHTTPClient overrides the client used for GitHub API calls made by this package.
+func SetHTTPClient(client *http.Client) {
+	if client == nil {
+		return
+	}
+	httpClient = client
+}
+
+// FetchGitHubProfileIconURL returns the avatar URL for the given GitHub login.
+func FetchGitHubProfileIconURL(login string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/users/%s", apiBaseURL, login), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch GitHub profile: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %d fetching GitHub profile for %s", resp.StatusCode, login)
+	}
+
+	var user struct {
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return "", fmt.Errorf("failed to decode GitHub profile response: %w", err)
+	}
+
+	return user.AvatarURL, nil
+}
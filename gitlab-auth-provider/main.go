@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/obot-platform/tools/auth-providers-common/pkg/authprovider"
+	"github.com/obot-platform/tools/auth-providers-common/pkg/env"
+)
+
+type Options struct {
+	authprovider.CommonOptions
+
+	ClientID          string  `env:"OBOT_GITLAB_AUTH_PROVIDER_CLIENT_ID"`
+	ClientSecret      string  `env:"OBOT_GITLAB_AUTH_PROVIDER_CLIENT_SECRET"`
+	GitLabGroups      *string `usage:"restrict logins to members of any of these GitLab groups (comma-separated list)" optional:"true" env:"OBOT_GITLAB_AUTH_PROVIDER_GROUPS"`
+	GitLabAllowedOrgs *string `usage:"restrict logins to members of these groups or group:subgroup pairs, e.g. group1:team-a,group1:team-b,group2:* (comma-separated)" optional:"true" env:"OBOT_GITLAB_AUTH_PROVIDER_ALLOWED_ORGS"`
+}
+
+func main() {
+	var opts Options
+	if err := env.LoadEnvForStruct(&opts); err != nil {
+		fmt.Printf("failed to load options: %v\n", err)
+		os.Exit(1)
+	}
+
+	var allowedOrgs *authprovider.AllowedOrgs
+	if opts.GitLabAllowedOrgs != nil {
+		var err error
+		allowedOrgs, err = authprovider.ParseAllowedOrgs(*opts.GitLabAllowedOrgs)
+		if err != nil {
+			fmt.Printf("failed to parse allowed orgs: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	authprovider.Run(&gitlabProvider{opts: opts}, opts.CommonOptions, allowedOrgs)
+}